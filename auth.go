@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// authMode selects how createLogHandler authenticates incoming requests.
+type authMode string
+
+const (
+	authModeNone   authMode = "none"
+	authModeBearer authMode = "bearer"
+	authModeHMAC   authMode = "hmac"
+)
+
+// hmacScope controls which bytes an HMAC signature is computed over: the
+// raw request body as received on the wire, or the body after gzip
+// decompression. GitHub/Stripe-style webhooks sign the raw (possibly
+// compressed) payload, so that is the default.
+type hmacScope string
+
+const (
+	hmacScopeRaw          hmacScope = "raw"
+	hmacScopeDecompressed hmacScope = "decompressed"
+)
+
+// authConfig holds the settings needed to verify a request under the
+// configured authMode.
+type authConfig struct {
+	mode       authMode
+	token      string
+	hmacSecret string
+	hmacScope  hmacScope
+}
+
+// authConfigFromEnv builds an authConfig from AUTH_MODE (none|bearer|hmac),
+// AUTH_TOKEN, AUTH_HMAC_SECRET and AUTH_HMAC_SCOPE (raw|decompressed).
+func authConfigFromEnv() (authConfig, error) {
+	mode := authMode(os.Getenv("AUTH_MODE"))
+	if mode == "" {
+		mode = authModeNone
+	}
+
+	scope := hmacScope(os.Getenv("AUTH_HMAC_SCOPE"))
+	if scope == "" {
+		scope = hmacScopeRaw
+	}
+
+	cfg := authConfig{
+		mode:       mode,
+		token:      os.Getenv("AUTH_TOKEN"),
+		hmacSecret: os.Getenv("AUTH_HMAC_SECRET"),
+		hmacScope:  scope,
+	}
+
+	switch mode {
+	case authModeNone:
+	case authModeBearer:
+		if cfg.token == "" {
+			return authConfig{}, fmt.Errorf("AUTH_MODE=bearer requires AUTH_TOKEN")
+		}
+	case authModeHMAC:
+		if cfg.hmacSecret == "" {
+			return authConfig{}, fmt.Errorf("AUTH_MODE=hmac requires AUTH_HMAC_SECRET")
+		}
+		if scope != hmacScopeRaw && scope != hmacScopeDecompressed {
+			return authConfig{}, fmt.Errorf("invalid AUTH_HMAC_SCOPE %q", scope)
+		}
+	default:
+		return authConfig{}, fmt.Errorf("unknown AUTH_MODE %q", mode)
+	}
+
+	return cfg, nil
+}
+
+// verifyBearer checks the Authorization header against the configured
+// static token using a constant-time comparison.
+func (c authConfig) verifyBearer(header string) bool {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	provided := strings.TrimPrefix(header, prefix)
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(c.token)) == 1
+}
+
+// verifyHMAC checks an "X-Signature: sha256=<hex>" header against an
+// HMAC-SHA256 of body computed with the shared secret.
+func (c authConfig) verifyHMAC(body []byte, header string) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	provided, err := hex.DecodeString(strings.TrimPrefix(header, prefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.hmacSecret))
+	mac.Write(body)
+	return hmac.Equal(provided, mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateLogHandlerBearerAuth(t *testing.T) {
+	auth := authConfig{mode: authModeBearer, token: "s3cret"}
+	handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), auth, defaultMaxDecompressedBytes)
+
+	t.Run("missing token rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(`{"a":1}`))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %d", rr.Code)
+		}
+	})
+
+	t.Run("correct token accepted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(`{"a":1}`))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", rr.Code)
+		}
+	})
+}
+
+func TestCreateLogHandlerHMACAuth(t *testing.T) {
+	secret := "whsec_test"
+	body := `{"a":1}`
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	sig := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	auth := authConfig{mode: authModeHMAC, hmacSecret: secret, hmacScope: hmacScopeRaw}
+	handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), auth, defaultMaxDecompressedBytes)
+
+	t.Run("valid signature accepted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(body))
+		req.Header.Set("X-Signature", sig)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", rr.Code)
+		}
+	})
+
+	t.Run("invalid signature rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(body))
+		req.Header.Set("X-Signature", "sha256=deadbeef")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %d", rr.Code)
+		}
+	})
+}
+
+func TestAuthConfigFromEnvValidation(t *testing.T) {
+	t.Run("bearer without token errors", func(t *testing.T) {
+		t.Setenv("AUTH_MODE", "bearer")
+		t.Setenv("AUTH_TOKEN", "")
+		if _, err := authConfigFromEnv(); err == nil {
+			t.Error("expected error when AUTH_TOKEN is unset")
+		}
+	})
+
+	t.Run("unknown mode errors", func(t *testing.T) {
+		t.Setenv("AUTH_MODE", "fingerprint")
+		if _, err := authConfigFromEnv(); err == nil {
+			t.Error("expected error for unknown AUTH_MODE")
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// isBatchRequest reports whether the request should be treated as an
+// NDJSON batch: one independently-validated record per line.
+func isBatchRequest(r *http.Request) bool {
+	if strings.Contains(strings.ToLower(r.Header.Get("Content-Type")), "application/x-ndjson") {
+		return true
+	}
+	return r.URL.Query().Get("batch") == "1"
+}
+
+// batchRecordError reports why a single line of an NDJSON batch was
+// rejected, without failing the rest of the batch.
+type batchRecordError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type batchResponse struct {
+	Status   string             `json:"status"`
+	Accepted int                `json:"accepted"`
+	Errors   []batchRecordError `json:"errors,omitempty"`
+}
+
+// handleBatch validates and forwards each line of body independently,
+// collecting per-line errors instead of failing the whole batch on one bad
+// line.
+func handleBatch(w http.ResponseWriter, r *http.Request, body []byte, sinks *sinkManager, meta map[string]string) {
+	lines := strings.Split(string(body), "\n")
+
+	var errs []batchRecordError
+	accepted := 0
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		compacted := &bytes.Buffer{}
+		if err := json.Compact(compacted, []byte(line)); err != nil {
+			errs = append(errs, batchRecordError{Line: i + 1, Error: "invalid JSON"})
+			continue
+		}
+
+		if sinks.async {
+			sinks.Enqueue(compacted.Bytes(), meta)
+			accepted++
+			continue
+		}
+
+		if err := sinks.Write(r.Context(), compacted.Bytes(), meta); err != nil {
+			errs = append(errs, batchRecordError{Line: i + 1, Error: "failed to forward to sinks"})
+			continue
+		}
+		accepted++
+	}
+
+	status := http.StatusOK
+	if sinks.async {
+		status = http.StatusAccepted
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(batchResponse{Status: "processed", Accepted: accepted, Errors: errs})
+}
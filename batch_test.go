@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateLogHandlerNDJSONBatch(t *testing.T) {
+	body := strings.Join([]string{
+		`{"service":"a"}`,
+		`not json`,
+		`{"service":"b"}`,
+	}, "\n")
+
+	handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp batchResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if resp.Accepted != 2 {
+		t.Errorf("expected 2 accepted records, got %d", resp.Accepted)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Line != 2 {
+		t.Errorf("expected a single error on line 2, got %+v", resp.Errors)
+	}
+}
+
+func TestCreateLogHandlerBatchQueryParam(t *testing.T) {
+	handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs?batch=1", strings.NewReader(`{"service":"a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var resp batchResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if resp.Accepted != 1 {
+		t.Errorf("expected 1 accepted record, got %d", resp.Accepted)
+	}
+}
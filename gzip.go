@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const defaultGzipThreshold = 1024
+
+// gzipThresholdFromEnv reads GZIP_MIN_BYTES, the minimum response size (in
+// bytes) GzipResponseHandler will compress.
+func gzipThresholdFromEnv() int {
+	v := os.Getenv("GZIP_MIN_BYTES")
+	if v == "" {
+		return defaultGzipThreshold
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return defaultGzipThreshold
+	}
+	return n
+}
+
+// bufferingResponseWriter records a handler's response instead of writing it
+// immediately, so GzipResponseHandler can inspect the total size before
+// deciding whether compression is worthwhile.
+type bufferingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferingResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferingResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// GzipResponseHandler gzip-compresses responses at or above thresholdBytes
+// when the client sent "Accept-Encoding: gzip", leaving smaller responses
+// uncompressed to avoid the overhead outweighing the savings.
+func GzipResponseHandler(thresholdBytes int) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := &bufferingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+
+			if buf.body.Len() < thresholdBytes {
+				w.WriteHeader(buf.status)
+				w.Write(buf.body.Bytes())
+				return
+			}
+
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Add("Vary", "Accept-Encoding")
+			w.WriteHeader(buf.status)
+
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			gz.Write(buf.body.Bytes())
+		})
+	}
+}
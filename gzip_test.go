@@ -0,0 +1,77 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipResponseHandlerCompressesAboveThreshold(t *testing.T) {
+	payload := strings.Repeat("x", 2048)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(payload))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	GzipResponseHandler(1024)(next).ServeHTTP(rr, req)
+
+	if rr.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected Content-Encoding: gzip, got %q", rr.Header().Get("Content-Encoding"))
+	}
+
+	gr, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("response body is not valid gzip: %v", err)
+	}
+	decoded, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("failed to decompress response: %v", err)
+	}
+	if string(decoded) != payload {
+		t.Error("decompressed body does not match original payload")
+	}
+}
+
+func TestGzipResponseHandlerSkipsSmallResponses(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("tiny"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	GzipResponseHandler(1024)(next).ServeHTTP(rr, req)
+
+	if rr.Header().Get("Content-Encoding") == "gzip" {
+		t.Error("small response should not be compressed")
+	}
+	if rr.Body.String() != "tiny" {
+		t.Errorf("expected uncompressed body to pass through, got %q", rr.Body.String())
+	}
+}
+
+func TestGzipResponseHandlerSkipsWithoutAcceptEncoding(t *testing.T) {
+	payload := strings.Repeat("x", 2048)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(payload))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs", nil)
+	rr := httptest.NewRecorder()
+
+	GzipResponseHandler(1024)(next).ServeHTTP(rr, req)
+
+	if rr.Header().Get("Content-Encoding") == "gzip" {
+		t.Error("response should not be compressed without Accept-Encoding: gzip")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HealthChecker is implemented by sinks that can report whether their
+// downstream destination is currently reachable. Sinks that don't
+// implement it (e.g. stdoutSink) are treated as always healthy.
+type HealthChecker interface {
+	Healthy(ctx context.Context) error
+}
+
+// Healthy checks the directory containing the sink's file is writable.
+func (s *fileSink) Healthy(_ context.Context) error {
+	dir := filepath.Dir(s.path)
+	probe := filepath.Join(dir, ".log-webhook-health-check")
+
+	f, err := os.OpenFile(probe, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("file sink: %s is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(probe)
+	return nil
+}
+
+// Healthy reports the syslog connection as healthy once dialed; a dropped
+// connection will surface as a write error instead.
+func (s *syslogSink) Healthy(_ context.Context) error {
+	if s.writer == nil {
+		return fmt.Errorf("syslog sink: not connected")
+	}
+	return nil
+}
+
+// Healthy issues a lightweight GET against the sink's push endpoint to
+// confirm it's reachable.
+func (s *httpPushSink) Healthy(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("%s sink: build health request: %w", s.name, err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s sink: unreachable: %w", s.name, err)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// Healthy reports whether every configured sink that implements
+// HealthChecker is currently reachable.
+func (m *sinkManager) Healthy(ctx context.Context) error {
+	for _, s := range m.sinks {
+		checker, ok := s.(HealthChecker)
+		if !ok {
+			continue
+		}
+		if err := checker.Healthy(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// livezHandler always reports healthy once the process is up and serving.
+func livezHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, `{"status":"healthy"}`)
+}
+
+// readyzHandler returns 503 until every configured sink reports healthy.
+func readyzHandler(sinks *sinkManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := sinks.Healthy(ctx); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, `{"status":"not ready","error":%q}`, err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"status":"ready"}`)
+	}
+}
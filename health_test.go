@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeHealthSink struct {
+	name string
+	err  error
+}
+
+func (s fakeHealthSink) Name() string                                           { return s.name }
+func (s fakeHealthSink) Write(context.Context, []byte, map[string]string) error { return nil }
+func (s fakeHealthSink) Healthy(context.Context) error                          { return s.err }
+
+func TestReadyzReturns200WhenAllSinksHealthy(t *testing.T) {
+	mgr := newSinkManager([]Sink{fakeHealthSink{name: "a"}, stdoutSink{}}, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr := httptest.NewRecorder()
+
+	readyzHandler(mgr)(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestReadyzReturns503WhenASinkIsUnhealthy(t *testing.T) {
+	mgr := newSinkManager([]Sink{fakeHealthSink{name: "a", err: errors.New("unreachable")}}, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr := httptest.NewRecorder()
+
+	readyzHandler(mgr)(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
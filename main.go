@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -12,27 +13,69 @@ import (
 	"strings"
 )
 
-// createLogHandler creates the log webhook handler
-func createLogHandler() http.HandlerFunc {
+// createLogHandler creates the log webhook handler. Every validated record
+// is forwarded to sinks: synchronously (200 once all sinks succeed, 5xx if
+// any fail) unless the manager is configured for async mode, in which case
+// the record is enqueued and the handler ACKs 202 immediately. Only POST is
+// accepted, and requests are authenticated according to auth.mode. A
+// request with Content-Type: application/x-ndjson (or ?batch=1) is treated
+// as NDJSON: each line is validated and forwarded independently.
+func createLogHandler(sinks *sinkManager, auth authConfig, maxDecompressedBytes int64) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Set response headers
 		w.Header().Set("Content-Type", "application/json")
 
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		if auth.mode == authModeBearer && !auth.verifyBearer(r.Header.Get("Authorization")) {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
 		defer r.Body.Close()
 
+		// Read the raw request body up front so an HMAC signature declared
+		// over the compressed payload can be verified before decompression.
+		rawBody, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, `{"error":"request body too large"}`, http.StatusRequestEntityTooLarge)
+				return
+			}
+			log.Printf("Error reading request body: %v", err)
+			http.Error(w, `{"error":"Failed to read request body"}`, http.StatusBadRequest)
+			return
+		}
+
+		if auth.mode == authModeHMAC && auth.hmacScope == hmacScopeRaw {
+			if !auth.verifyHMAC(rawBody, r.Header.Get("X-Signature")) {
+				http.Error(w, `{"error":"invalid signature"}`, http.StatusUnauthorized)
+				return
+			}
+		}
+
 		// Create a reader that may need gzip decompression
-		var reader io.Reader = r.Body
+		var reader io.Reader = bytes.NewReader(rawBody)
 
 		// Check if the request body is gzip-encoded
 		if strings.Contains(strings.ToLower(r.Header.Get("Content-Encoding")), "gzip") {
-			gzipReader, err := gzip.NewReader(r.Body)
+			gzipReader, err := gzip.NewReader(reader)
 			if err != nil {
 				log.Printf("Error creating gzip reader: %v", err)
 				http.Error(w, `{"error":"Failed to create gzip reader"}`, http.StatusBadRequest)
 				return
 			}
 			defer gzipReader.Close()
-			reader = gzipReader
+			// Bound the decompressed size independently of MaxBytesHandler,
+			// which only limits the compressed bytes read off the wire: a
+			// small gzip payload can expand far beyond that on decompression
+			// and wedge the process otherwise.
+			reader = io.LimitReader(gzipReader, maxDecompressedBytes+1)
 		}
 
 		// Read request body
@@ -42,6 +85,24 @@ func createLogHandler() http.HandlerFunc {
 			http.Error(w, `{"error":"Failed to read request body"}`, http.StatusBadRequest)
 			return
 		}
+		if int64(len(body)) > maxDecompressedBytes {
+			http.Error(w, `{"error":"decompressed body too large"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if auth.mode == authModeHMAC && auth.hmacScope == hmacScopeDecompressed {
+			if !auth.verifyHMAC(body, r.Header.Get("X-Signature")) {
+				http.Error(w, `{"error":"invalid signature"}`, http.StatusUnauthorized)
+				return
+			}
+		}
+
+		meta := map[string]string{"remote_addr": r.RemoteAddr}
+
+		if isBatchRequest(r) {
+			handleBatch(w, r, body, sinks, meta)
+			return
+		}
 
 		// Validate and compact JSON
 		compactedJSON := &bytes.Buffer{}
@@ -51,8 +112,18 @@ func createLogHandler() http.HandlerFunc {
 			return
 		}
 
-		// Log the compacted JSON
-		fmt.Println(compactedJSON.String())
+		if sinks.async {
+			sinks.Enqueue(compactedJSON.Bytes(), meta)
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"status":"accepted"}`)
+			return
+		}
+
+		if err := sinks.Write(r.Context(), compactedJSON.Bytes(), meta); err != nil {
+			log.Printf("Error writing to sinks: %v", err)
+			http.Error(w, `{"error":"Failed to forward log to one or more sinks"}`, http.StatusBadGateway)
+			return
+		}
 
 		// Send success response
 		w.WriteHeader(http.StatusOK)
@@ -72,17 +143,36 @@ func main() {
 		endpoint = "/v1/logs"
 	}
 
+	sinks, err := sinkManagerFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure log sinks: %v", err)
+	}
+
+	auth, err := authConfigFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure authentication: %v", err)
+	}
+
 	// Create HTTP server with timeouts
 	mux := http.NewServeMux()
-	mux.HandleFunc(endpoint, createLogHandler())
+	mux.HandleFunc(endpoint, createLogHandler(sinks, auth, maxDecompressedBytesFromEnv()))
 
-	// Add health check endpoint
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprint(w, `{"status":"healthy"}`)
-	})
+	// Liveness, readiness and metrics endpoints
+	mux.HandleFunc("/livez", livezHandler)
+	mux.HandleFunc("/readyz", readyzHandler(sinks))
+	mux.HandleFunc("/metrics", metricsHandlerFunc)
+
+	mws := []Middleware{RecoveryHandler, LoggingHandler, MetricsHandler, MaxBytesHandler(maxBodyBytesFromEnv())}
+	if cors := corsConfigFromEnv(); len(cors.origins) > 0 {
+		mws = append(mws, CORS(cors))
+	}
+	mws = append(mws, GzipResponseHandler(gzipThresholdFromEnv()))
+	handler := chain(mux, mws...)
+
+	server := newServer(":"+port, handler)
 
 	log.Printf("log-webhook listening on :%s at endpoint %s", port, endpoint)
-	log.Fatal(http.ListenAndServe(":"+port, mux))
+	if err := runWithGracefulShutdown(server, sinks, shutdownTimeoutFromEnv()); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
 }
@@ -2,8 +2,7 @@ package main
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
+	"compress/gzip"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -90,7 +89,7 @@ func TestLogWebhookHandler(t *testing.T) {
 			rr := httptest.NewRecorder()
 
 			// Create handler and serve request
-			handler := createLogHandler()
+			handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes)
 
 			handler.ServeHTTP(rr, req)
 
@@ -145,7 +144,7 @@ func TestLogWebhookEndpoint(t *testing.T) {
 
 	// Create a new mux and register the handler
 	mux := http.NewServeMux()
-	mux.HandleFunc("/v1/logs", createLogHandler())
+	mux.HandleFunc("/v1/logs", createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes))
 
 	mux.ServeHTTP(rr, req)
 
@@ -183,9 +182,9 @@ func TestHTTPMethods(t *testing.T) {
 		expectedStatus int
 	}{
 		{http.MethodPost, http.StatusOK},
-		{http.MethodGet, http.StatusOK},    // Handler doesn't restrict methods
-		{http.MethodPut, http.StatusOK},    // Handler doesn't restrict methods
-		{http.MethodDelete, http.StatusOK}, // Handler doesn't restrict methods
+		{http.MethodGet, http.StatusMethodNotAllowed},
+		{http.MethodPut, http.StatusMethodNotAllowed},
+		{http.MethodDelete, http.StatusMethodNotAllowed},
 	}
 
 	for _, m := range methods {
@@ -198,44 +197,25 @@ func TestHTTPMethods(t *testing.T) {
 			req := httptest.NewRequest(m.method, "/v1/logs", body)
 			rr := httptest.NewRecorder()
 
-			handler := createLogHandler()
-
-			// Handle empty body case for GET requests
-			if m.method == http.MethodGet {
-				handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					defer r.Body.Close()
-					b, err := io.ReadAll(r.Body)
-					if err != nil {
-						http.Error(w, "read error", http.StatusBadRequest)
-						return
-					}
-
-					if len(b) == 0 {
-						w.WriteHeader(http.StatusOK)
-						return
-					}
-
-					dst := &bytes.Buffer{}
-					if err := json.Compact(dst, b); err != nil {
-						http.Error(w, "invalid json", http.StatusBadRequest)
-						return
-					}
-					w.WriteHeader(http.StatusOK)
-				})
-			}
-
+			handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes)
 			handler.ServeHTTP(rr, req)
 
 			if rr.Code != m.expectedStatus {
 				t.Errorf("method %s returned wrong status code: got %v want %v", m.method, rr.Code, m.expectedStatus)
 			}
+
+			if m.method != http.MethodPost {
+				if allow := rr.Header().Get("Allow"); allow != http.MethodPost {
+					t.Errorf("expected Allow: POST header, got %q", allow)
+				}
+			}
 		})
 	}
 }
 
 // Benchmark tests
 func BenchmarkLogWebhookHandler(b *testing.B) {
-	handler := createLogHandler()
+	handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes)
 
 	requestBody := `{"service": "benchmark", "message": "test message", "level": "info"}`
 
@@ -304,27 +284,22 @@ func TestEnvironmentVariables(t *testing.T) {
 	})
 }
 
-func TestHealthCheckEndpoint(t *testing.T) {
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+func TestLivezEndpoint(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
 	rr := httptest.NewRecorder()
 
-	// Create a new mux and register the health handler
 	mux := http.NewServeMux()
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprint(w, `{"status":"healthy"}`)
-	})
+	mux.HandleFunc("/livez", livezHandler)
 
 	mux.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusOK {
-		t.Errorf("health check returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		t.Errorf("livez returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
 	}
 
 	expectedBody := `{"status":"healthy"}`
 	if strings.TrimSpace(rr.Body.String()) != expectedBody {
-		t.Errorf("health check returned unexpected body: got %v want %v", rr.Body.String(), expectedBody)
+		t.Errorf("livez returned unexpected body: got %v want %v", rr.Body.String(), expectedBody)
 	}
 
 	contentType := rr.Header().Get("Content-Type")
@@ -332,3 +307,48 @@ func TestHealthCheckEndpoint(t *testing.T) {
 		t.Errorf("expected Content-Type application/json, got %s", contentType)
 	}
 }
+
+func gzipCompress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		t.Fatalf("failed to gzip test payload: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestLogWebhookHandlerAcceptsGzippedBody(t *testing.T) {
+	payload := []byte(`{"service": "test", "message": "hello"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", bytes.NewReader(gzipCompress(t, payload)))
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes)
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestLogWebhookHandlerRejectsOversizedDecompressedBody(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 1024)
+	body := []byte(`{"service": "test", "message": "` + string(payload) + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", bytes.NewReader(gzipCompress(t, body)))
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	// A tiny decompressed cap forces the handler to reject the payload even
+	// though the compressed body itself is well within MaxBytesHandler's
+	// limit, guarding against a small gzip bomb expanding unboundedly.
+	handler := createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, 16)
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
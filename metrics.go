@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// counterVec is a minimal Prometheus-style counter keyed by a label set,
+// just enough to back /metrics without pulling in an external client
+// library.
+type counterVec struct {
+	labelNames []string
+
+	mu      sync.Mutex
+	entries map[string]*counterEntry
+}
+
+type counterEntry struct {
+	labels []string
+	value  int64
+}
+
+func newCounterVec(labelNames ...string) *counterVec {
+	return &counterVec{labelNames: labelNames, entries: map[string]*counterEntry{}}
+}
+
+func (c *counterVec) inc(labelValues ...string) {
+	key := strings.Join(labelValues, "\x1f")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &counterEntry{labels: append([]string(nil), labelValues...)}
+		c.entries[key] = e
+	}
+	e.value++
+}
+
+func (c *counterVec) writeTo(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.entries) {
+		e := c.entries[key]
+		fmt.Fprintf(w, "%s{%s} %d\n", name, labelPairs(c.labelNames, e.labels), e.value)
+	}
+}
+
+// histogramVec is a minimal Prometheus-style histogram keyed by a label
+// set, using a fixed set of cumulative buckets.
+type histogramVec struct {
+	labelNames []string
+	buckets    []float64
+
+	mu      sync.Mutex
+	entries map[string]*histogramEntry
+}
+
+type histogramEntry struct {
+	labels []string
+	counts []int64
+	sum    float64
+	count  int64
+}
+
+func newHistogramVec(buckets []float64, labelNames ...string) *histogramVec {
+	return &histogramVec{labelNames: labelNames, buckets: buckets, entries: map[string]*histogramEntry{}}
+}
+
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\x1f")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e, ok := h.entries[key]
+	if !ok {
+		e = &histogramEntry{labels: append([]string(nil), labelValues...), counts: make([]int64, len(h.buckets))}
+		h.entries[key] = e
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			e.counts[i]++
+		}
+	}
+	e.sum += value
+	e.count++
+}
+
+func (h *histogramVec) writeTo(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.entries) {
+		e := h.entries[key]
+		base := labelPairs(h.labelNames, e.labels)
+
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket{%s} %d\n", name, withLE(base, strconv.FormatFloat(bound, 'g', -1, 64)), e.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s} %d\n", name, withLE(base, "+Inf"), e.count)
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", name, base, e.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, base, e.count)
+	}
+}
+
+func withLE(base, le string) string {
+	pair := fmt.Sprintf(`le=%q`, le)
+	if base == "" {
+		return pair
+	}
+	return base + "," + pair
+}
+
+func labelPairs(names, values []string) string {
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = fmt.Sprintf("%s=%q", n, values[i])
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Metrics instrumentation, shared process-wide. Kept as package-level vars
+// (rather than threaded through every call site) since this mirrors how a
+// real Prometheus client registry is used: one global registry per process.
+var (
+	requestsTotal     = newCounterVec("status", "method")
+	requestDuration   = newHistogramVec([]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+	payloadBytes      = newHistogramVec([]float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576})
+	sinkWritesTotal   = newCounterVec("sink", "result")
+	sinkWriteDuration = newHistogramVec([]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}, "sink")
+)
+
+// metricsResponseWriter captures the status code written by the downstream
+// handler so MetricsHandler can label logwebhook_requests_total with it.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *metricsResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// MetricsHandler records per-request counters and histograms. It lives in
+// the middleware layer so instrumentation composes with the
+// recovery/CORS/logging chain without touching createLogHandler.
+func MetricsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		mrw := &metricsResponseWriter{ResponseWriter: w}
+
+		next.ServeHTTP(mrw, r)
+
+		status := mrw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		requestsTotal.inc(strconv.Itoa(status), r.Method)
+		requestDuration.observe(time.Since(start).Seconds())
+		if r.ContentLength > 0 {
+			payloadBytes.observe(float64(r.ContentLength))
+		}
+	})
+}
+
+// metricsHandlerFunc serves the process's metrics in Prometheus text
+// exposition format.
+func metricsHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	requestsTotal.writeTo(w, "logwebhook_requests_total", "Total HTTP requests by status and method.")
+	requestDuration.writeTo(w, "logwebhook_request_duration_seconds", "HTTP request duration in seconds.")
+	payloadBytes.writeTo(w, "logwebhook_payload_bytes", "Size of accepted request payloads in bytes.")
+	sinkWritesTotal.writeTo(w, "logwebhook_sink_writes_total", "Total sink write attempts by sink and result.")
+	sinkWriteDuration.writeTo(w, "logwebhook_sink_write_duration_seconds", "Sink write duration in seconds by sink.")
+}
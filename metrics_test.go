@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandlerFuncExposesExpectedSeries(t *testing.T) {
+	requestsTotal.inc("200", http.MethodPost)
+	sinkWritesTotal.inc("stdout", "success")
+	requestDuration.observe(0.02)
+	sinkWriteDuration.observe(0.01, "stdout")
+	payloadBytes.observe(128)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+
+	metricsHandlerFunc(rr, req)
+
+	body := rr.Body.String()
+	for _, want := range []string{
+		"logwebhook_requests_total",
+		"logwebhook_request_duration_seconds",
+		"logwebhook_payload_bytes",
+		"logwebhook_sink_writes_total",
+		"logwebhook_sink_write_duration_seconds",
+		`status="200"`,
+		`sink="stdout"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected /metrics output to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsHandlerRecordsRequestOutcome(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs", nil)
+	rr := httptest.NewRecorder()
+
+	MetricsHandler(next).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusTeapot {
+		t.Errorf("expected wrapped handler's status to pass through, got %d", rr.Code)
+	}
+}
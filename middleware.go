@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Middleware wraps an http.Handler with additional behavior. Middlewares
+// compose with plain function composition so each one stays independently
+// toggleable and testable.
+type Middleware func(http.Handler) http.Handler
+
+// chain applies middlewares in listed order, so the first middleware is the
+// outermost one a request passes through.
+func chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// RecoveryHandler recovers from panics raised anywhere downstream
+// (including inside a sink write), logs the stack trace, and responds with
+// a 500 JSON error instead of letting the server crash the connection.
+func RecoveryHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Printf("panic recovered handling %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack())
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"error":"internal server error"}`)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsConfig describes the CORS policy applied by CORS.
+type corsConfig struct {
+	origins []string
+	methods []string
+	headers []string
+}
+
+// corsConfigFromEnv builds a corsConfig from CORS_ORIGINS, CORS_METHODS and
+// CORS_HEADERS (comma-separated lists). An empty/unset CORS_ORIGINS means
+// CORS is not applicable; callers should skip the middleware in that case.
+func corsConfigFromEnv() corsConfig {
+	cfg := corsConfig{
+		methods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		headers: []string{"Content-Type", "Authorization", "X-Signature"},
+	}
+	if v := os.Getenv("CORS_ORIGINS"); v != "" {
+		cfg.origins = splitAndTrim(v)
+	}
+	if v := os.Getenv("CORS_METHODS"); v != "" {
+		cfg.methods = splitAndTrim(v)
+	}
+	if v := os.Getenv("CORS_HEADERS"); v != "" {
+		cfg.headers = splitAndTrim(v)
+	}
+	return cfg
+}
+
+func splitAndTrim(v string) []string {
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (c corsConfig) allowsOrigin(origin string) bool {
+	for _, o := range c.origins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS returns a middleware that sets Access-Control-* headers for allowed
+// origins and answers preflight OPTIONS requests directly, without
+// forwarding them to next.
+func CORS(cfg corsConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin != "" && cfg.allowsOrigin(origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.methods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.headers, ", "))
+			}
+
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// accessLogger writes access log lines to stderr, kept separate from the
+// ingested payload stream createLogHandler writes to stdout.
+var accessLogger = log.New(os.Stderr, "", 0)
+
+// maxAccessLogBodyBytes bounds how much of a response body logAccess keeps
+// around for logging, so a large payload doesn't bloat the access log.
+const maxAccessLogBodyBytes = 2048
+
+// loggingResponseWriter captures the status code, body size and (bounded)
+// body written by the downstream handler so LoggingHandler can record them
+// after the fact.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+	body   bytes.Buffer
+}
+
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *loggingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	if remaining := maxAccessLogBodyBytes - w.body.Len(); remaining > 0 {
+		if remaining > len(b) {
+			remaining = len(b)
+		}
+		w.body.Write(b[:remaining])
+	}
+	return n, err
+}
+
+// LoggingHandler writes one Apache combined-log-style line per request to
+// stderr, separate from the ingested payload stream createLogHandler writes
+// to stdout. When the response went out gzip-compressed, the body is
+// omitted from the log entry instead of dumping compressed binary into it.
+func LoggingHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lrw := &loggingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(lrw, r)
+		logAccess(lrw, r, start)
+	})
+}
+
+func logAccess(lrw *loggingResponseWriter, r *http.Request, start time.Time) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	status := lrw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	line := fmt.Sprintf(`%s - - [%s] "%s %s %s" %d %d "%s" "%s"`,
+		host,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.URL.RequestURI(), r.Proto,
+		status, lrw.size,
+		r.Referer(), r.UserAgent(),
+	)
+
+	compressed := strings.Contains(strings.ToLower(lrw.Header().Get("Content-Encoding")), "gzip")
+	if !compressed && lrw.body.Len() > 0 {
+		line += fmt.Sprintf(" body=%q", lrw.body.String())
+	}
+
+	accessLogger.Println(line)
+}
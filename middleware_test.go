@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryHandlerRecoversPanic(t *testing.T) {
+	panicky := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs", nil)
+	rr := httptest.NewRecorder()
+
+	RecoveryHandler(panicky).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %s", ct)
+	}
+}
+
+func TestCORSPreflightRequest(t *testing.T) {
+	cfg := corsConfig{
+		origins: []string{"https://example.com"},
+		methods: []string{http.MethodPost, http.MethodOptions},
+		headers: []string{"Content-Type"},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("preflight request should not reach the wrapped handler")
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/logs", nil)
+	req.Header.Set("Origin", "https://example.com")
+	rr := httptest.NewRecorder()
+
+	CORS(cfg)(next).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("expected 204 for preflight, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Errorf("expected allowed origin echoed back, got %q", got)
+	}
+}
+
+func TestCORSRejectsDisallowedOrigin(t *testing.T) {
+	cfg := corsConfig{origins: []string{"https://allowed.example"}}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	rr := httptest.NewRecorder()
+
+	CORS(cfg)(next).ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no CORS header for disallowed origin, got %q", got)
+	}
+}
+
+func TestLoggingHandlerCapturesStatus(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs", nil)
+	rr := httptest.NewRecorder()
+
+	LoggingHandler(next).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusTeapot {
+		t.Errorf("expected wrapped handler's status to pass through, got %d", rr.Code)
+	}
+}
+
+func TestLoggingHandlerSuppressesCompressedBody(t *testing.T) {
+	old := accessLogger
+	defer func() { accessLogger = old }()
+
+	run := func(contentEncoding string) string {
+		var buf bytes.Buffer
+		accessLogger = log.New(&buf, "", 0)
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if contentEncoding != "" {
+				w.Header().Set("Content-Encoding", contentEncoding)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status":"success"}`))
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/logs", nil)
+		rr := httptest.NewRecorder()
+		LoggingHandler(next).ServeHTTP(rr, req)
+		return buf.String()
+	}
+
+	if line := run(""); !strings.Contains(line, `body="{\"status\":\"success\"}"`) {
+		t.Errorf("expected uncompressed response body in access log, got: %s", line)
+	}
+	if line := run("gzip"); strings.Contains(line, "body=") {
+		t.Errorf("expected compressed response body to be suppressed, got: %s", line)
+	}
+}
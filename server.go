@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultReadHeaderTimeout    = 5 * time.Second
+	defaultReadTimeout          = 30 * time.Second
+	defaultWriteTimeout         = 30 * time.Second
+	defaultIdleTimeout          = 120 * time.Second
+	defaultMaxBodyBytes         = 1 << 20  // 1 MiB
+	defaultMaxDecompressedBytes = 10 << 20 // 10 MiB
+	defaultShutdownTimeout      = 10 * time.Second
+)
+
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+func int64Env(key string, fallback int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// maxBodyBytesFromEnv reads MAX_BODY_BYTES, the largest request body
+// MaxBytesHandler will accept.
+func maxBodyBytesFromEnv() int64 {
+	return int64Env("MAX_BODY_BYTES", defaultMaxBodyBytes)
+}
+
+// maxDecompressedBytesFromEnv reads MAX_DECOMPRESSED_BYTES, the largest
+// payload createLogHandler will read out of a gzip-decoded body. This is
+// deliberately separate from MAX_BODY_BYTES: MaxBytesHandler only bounds the
+// compressed bytes read off the wire, and a small gzip payload can expand
+// far beyond that on decompression.
+func maxDecompressedBytesFromEnv() int64 {
+	return int64Env("MAX_DECOMPRESSED_BYTES", defaultMaxDecompressedBytes)
+}
+
+// shutdownTimeoutFromEnv reads SHUTDOWN_TIMEOUT, how long
+// runWithGracefulShutdown waits for in-flight requests and sink writes to
+// finish before exiting.
+func shutdownTimeoutFromEnv() time.Duration {
+	return durationEnv("SHUTDOWN_TIMEOUT", defaultShutdownTimeout)
+}
+
+// newServer builds the http.Server with env-driven timeouts, so a slowloris
+// client or an idle keep-alive connection can't wedge the process.
+func newServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: durationEnv("READ_HEADER_TIMEOUT", defaultReadHeaderTimeout),
+		ReadTimeout:       durationEnv("READ_TIMEOUT", defaultReadTimeout),
+		WriteTimeout:      durationEnv("WRITE_TIMEOUT", defaultWriteTimeout),
+		IdleTimeout:       durationEnv("IDLE_TIMEOUT", defaultIdleTimeout),
+	}
+}
+
+// MaxBytesHandler rejects request bodies larger than maxBytes. The actual
+// 413 response, matching createLogHandler's JSON error envelope, is
+// produced where the body is read since that's the first point a
+// MaxBytesReader failure surfaces.
+func MaxBytesHandler(maxBytes int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// runWithGracefulShutdown starts server and blocks until it exits, either
+// from a fatal ListenAndServe error or a SIGINT/SIGTERM that triggers a
+// graceful Shutdown bounded by drainTimeout. server.Shutdown only waits for
+// in-flight HTTP handlers, which in async sink mode have already returned
+// (202) before their enqueued sink writes finish, so it's followed by
+// sinks.Drain to let those buffered writes complete before the process
+// exits, bounded by the same drainTimeout.
+func runWithGracefulShutdown(server *http.Server, sinks *sinkManager, drainTimeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-sigCh:
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+		shutdownErr := server.Shutdown(ctx)
+		if err := sinks.Drain(ctx); err != nil {
+			log.Printf("timed out waiting for buffered sink writes to finish: %v", err)
+		}
+		return shutdownErr
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// slowSink sleeps for delay before completing a write, to simulate a sink
+// write that is still in flight when shutdown begins.
+type slowSink struct {
+	delay time.Duration
+	done  int32
+}
+
+func (slowSink) Name() string { return "slow" }
+
+func (s *slowSink) Write(_ context.Context, _ []byte, _ map[string]string) error {
+	time.Sleep(s.delay)
+	atomic.StoreInt32(&s.done, 1)
+	return nil
+}
+
+func (s *slowSink) wrote() bool { return atomic.LoadInt32(&s.done) == 1 }
+
+func TestMaxBytesHandlerRejectsOversizeBody(t *testing.T) {
+	handler := chain(
+		createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes),
+		MaxBytesHandler(10),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(`{"service":"too big for the limit"}`))
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestMaxBytesHandlerAllowsBodyUnderLimit(t *testing.T) {
+	handler := chain(
+		createLogHandler(newSinkManager([]Sink{stdoutSink{}}, false), authConfig{mode: authModeNone}, defaultMaxDecompressedBytes),
+		MaxBytesHandler(1<<20),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(`{"service":"test"}`))
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRunWithGracefulShutdownStopsOnSignal(t *testing.T) {
+	server := newServer("127.0.0.1:0", http.NewServeMux())
+	sinks := newSinkManager([]Sink{stdoutSink{}}, true)
+
+	done := make(chan error, 1)
+	go func() { done <- runWithGracefulShutdown(server, sinks, time.Second) }()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to signal self: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down in time")
+	}
+}
+
+func TestRunWithGracefulShutdownWaitsForEnqueuedSinkWrites(t *testing.T) {
+	server := newServer("127.0.0.1:0", http.NewServeMux())
+	s := &slowSink{delay: 200 * time.Millisecond}
+	sinks := newSinkManager([]Sink{s}, true)
+	sinks.Enqueue([]byte(`{"k":"v"}`), nil)
+
+	done := make(chan error, 1)
+	go func() { done <- runWithGracefulShutdown(server, sinks, time.Second) }()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to signal self: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down in time")
+	}
+
+	if !s.wrote() {
+		t.Error("expected shutdown to wait for the enqueued sink write to finish")
+	}
+}
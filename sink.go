@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/syslog"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink forwards a single compacted JSON log record to a downstream
+// destination. Implementations must be safe for concurrent use.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, rawJSON []byte, meta map[string]string) error
+}
+
+// stdoutSink is the original "log to stdout" behavior, kept as the default
+// sink so the module still works out of the box with no configuration.
+type stdoutSink struct{}
+
+func (stdoutSink) Name() string { return "stdout" }
+
+func (stdoutSink) Write(_ context.Context, rawJSON []byte, _ map[string]string) error {
+	fmt.Println(string(rawJSON))
+	return nil
+}
+
+// fileSink appends each record as a line to a file on disk.
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file sink: LOG_SINK_FILE_PATH is required")
+	}
+	return &fileSink{path: path}, nil
+}
+
+func (s *fileSink) Name() string { return "file" }
+
+func (s *fileSink) Write(_ context.Context, rawJSON []byte, _ map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("file sink: open %s: %w", s.path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(rawJSON, '\n')); err != nil {
+		return fmt.Errorf("file sink: write: %w", err)
+	}
+	return nil
+}
+
+// syslogSink forwards records to a local or remote syslog daemon.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(network, addr string) (*syslogSink, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, "log-webhook")
+	if err != nil {
+		return nil, fmt.Errorf("syslog sink: dial %s %s: %w", network, addr, err)
+	}
+	return &syslogSink{writer: w}, nil
+}
+
+func (s *syslogSink) Name() string { return "syslog" }
+
+func (s *syslogSink) Write(_ context.Context, rawJSON []byte, _ map[string]string) error {
+	if err := s.writer.Info(string(rawJSON)); err != nil {
+		return fmt.Errorf("syslog sink: write: %w", err)
+	}
+	return nil
+}
+
+// httpPushSink is a small helper shared by the Loki and Elasticsearch sinks:
+// both forward a record as an HTTP POST with a JSON body built by a
+// sink-specific encoder.
+type httpPushSink struct {
+	name   string
+	url    string
+	client *http.Client
+	encode func(rawJSON []byte, meta map[string]string) ([]byte, string, error)
+}
+
+func (s *httpPushSink) Name() string { return s.name }
+
+func (s *httpPushSink) Write(ctx context.Context, rawJSON []byte, meta map[string]string) error {
+	body, contentType, err := s.encode(rawJSON, meta)
+	if err != nil {
+		return fmt.Errorf("%s sink: encode: %w", s.name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s sink: build request: %w", s.name, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s sink: request: %w", s.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s sink: unexpected status %s", s.name, resp.Status)
+	}
+	return nil
+}
+
+// encodeLokiPush wraps a record in Loki's push API stream format, using
+// meta as stream labels alongside a fixed job label.
+func encodeLokiPush(rawJSON []byte, meta map[string]string) ([]byte, string, error) {
+	labels := map[string]string{"job": "log-webhook"}
+	for k, v := range meta {
+		labels[k] = v
+	}
+
+	push := map[string]any{
+		"streams": []map[string]any{
+			{
+				"stream": labels,
+				"values": [][]string{{fmt.Sprintf("%d", time.Now().UnixNano()), string(rawJSON)}},
+			},
+		},
+	}
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, "application/json", nil
+}
+
+// encodeElasticsearchDoc passes the record through unchanged as the
+// document body; Elasticsearch accepts arbitrary JSON documents.
+func encodeElasticsearchDoc(rawJSON []byte, _ map[string]string) ([]byte, string, error) {
+	return rawJSON, "application/json", nil
+}
+
+func newLokiSink(url string) (*httpPushSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("loki sink: LOG_SINK_LOKI_URL is required")
+	}
+	return &httpPushSink{
+		name:   "loki",
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		encode: encodeLokiPush,
+	}, nil
+}
+
+func newElasticsearchSink(url, index string) (*httpPushSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("elasticsearch sink: LOG_SINK_ELASTICSEARCH_URL is required")
+	}
+	if index == "" {
+		index = "logs"
+	}
+	docURL := strings.TrimRight(url, "/") + "/" + index + "/_doc"
+	return &httpPushSink{
+		name:   "elasticsearch",
+		url:    docURL,
+		client: &http.Client{Timeout: 10 * time.Second},
+		encode: encodeElasticsearchDoc,
+	}, nil
+}
+
+// sinkManager fans a record out to every configured sink concurrently,
+// retrying each sink independently with exponential backoff so one slow or
+// failing destination never blocks the others or the HTTP response.
+type sinkManager struct {
+	sinks       []Sink
+	async       bool
+	maxRetries  int
+	baseBackoff time.Duration
+	inFlight    sync.WaitGroup
+}
+
+func newSinkManager(sinks []Sink, async bool) *sinkManager {
+	return &sinkManager{
+		sinks:       sinks,
+		async:       async,
+		maxRetries:  3,
+		baseBackoff: 100 * time.Millisecond,
+	}
+}
+
+type sinkResult struct {
+	sink string
+	err  error
+}
+
+// dispatch starts a goroutine per sink and returns a channel that receives
+// exactly len(sinks) results.
+func (m *sinkManager) dispatch(ctx context.Context, rawJSON []byte, meta map[string]string) <-chan sinkResult {
+	results := make(chan sinkResult, len(m.sinks))
+	for _, s := range m.sinks {
+		s := s
+		go func() {
+			results <- sinkResult{sink: s.Name(), err: writeWithRecover(func() error {
+				return m.writeWithRetry(ctx, s, rawJSON, meta)
+			})}
+		}()
+	}
+	return results
+}
+
+// writeWithRecover runs write in its own goroutine's call stack and
+// converts a panic into an error, so a panicking sink (sync or enqueued
+// async) never crashes the process the way an unrecovered goroutine panic
+// would.
+func writeWithRecover(write func() error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Printf("panic recovered in sink write: %v\n%s", rec, debug.Stack())
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return write()
+}
+
+func (m *sinkManager) writeWithRetry(ctx context.Context, s Sink, rawJSON []byte, meta map[string]string) error {
+	start := time.Now()
+
+	var err error
+	backoff := m.baseBackoff
+	for attempt := 0; attempt <= m.maxRetries; attempt++ {
+		if err = s.Write(ctx, rawJSON, meta); err == nil {
+			sinkWritesTotal.inc(s.Name(), "success")
+			sinkWriteDuration.observe(time.Since(start).Seconds(), s.Name())
+			return nil
+		}
+		if attempt == m.maxRetries {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			sinkWritesTotal.inc(s.Name(), "error")
+			sinkWriteDuration.observe(time.Since(start).Seconds(), s.Name())
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+
+	sinkWritesTotal.inc(s.Name(), "error")
+	sinkWriteDuration.observe(time.Since(start).Seconds(), s.Name())
+	return fmt.Errorf("%s: giving up after %d attempts: %w", s.Name(), m.maxRetries+1, err)
+}
+
+// Write dispatches to every sink and blocks until all of them finish,
+// returning the combined error if any sink ultimately failed.
+func (m *sinkManager) Write(ctx context.Context, rawJSON []byte, meta map[string]string) error {
+	results := m.dispatch(ctx, rawJSON, meta)
+	var failures []string
+	for range m.sinks {
+		if r := <-results; r.err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", r.sink, r.err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("sink write failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Enqueue dispatches to every sink without waiting for completion, logging
+// any eventual failures instead of surfacing them to the caller. The write is
+// tracked in m.inFlight so Drain can block shutdown until it finishes,
+// instead of letting the process exit mid-write.
+func (m *sinkManager) Enqueue(rawJSON []byte, meta map[string]string) {
+	m.inFlight.Add(1)
+	go func() {
+		defer m.inFlight.Done()
+		results := m.dispatch(context.Background(), rawJSON, meta)
+		for range m.sinks {
+			if r := <-results; r.err != nil {
+				log.Printf("async sink write failed: %s: %v", r.sink, r.err)
+			}
+		}
+	}()
+}
+
+// Drain blocks until every write enqueued via Enqueue has finished, or ctx is
+// done, whichever comes first. It returns ctx.Err() if the context expires
+// before all writes complete.
+func (m *sinkManager) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sinkManagerFromEnv builds a sinkManager from LOG_SINK (a comma-separated
+// list of stdout|file|syslog|loki|elasticsearch) and the per-sink settings
+// each implementation needs. It defaults to a single synchronous stdout
+// sink, matching the module's original behavior.
+func sinkManagerFromEnv() (*sinkManager, error) {
+	names := os.Getenv("LOG_SINK")
+	if names == "" {
+		names = "stdout"
+	}
+
+	var sinks []Sink
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		var (
+			s   Sink
+			err error
+		)
+		switch name {
+		case "stdout":
+			s = stdoutSink{}
+		case "file":
+			s, err = newFileSink(os.Getenv("LOG_SINK_FILE_PATH"))
+		case "syslog":
+			network := os.Getenv("LOG_SINK_SYSLOG_NETWORK")
+			s, err = newSyslogSink(network, os.Getenv("LOG_SINK_SYSLOG_ADDR"))
+		case "loki":
+			s, err = newLokiSink(os.Getenv("LOG_SINK_LOKI_URL"))
+		case "elasticsearch":
+			s, err = newElasticsearchSink(os.Getenv("LOG_SINK_ELASTICSEARCH_URL"), os.Getenv("LOG_SINK_ELASTICSEARCH_INDEX"))
+		default:
+			err = fmt.Errorf("unknown LOG_SINK value %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+
+	return newSinkManager(sinks, os.Getenv("LOG_SINK_ASYNC") == "true"), nil
+}
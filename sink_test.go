@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingSink records every Write call and can be made to fail a fixed
+// number of times before succeeding, to exercise retry behavior.
+type countingSink struct {
+	name       string
+	failTimes  int32
+	calls      int32
+	lastRecord []byte
+}
+
+func (s *countingSink) Name() string { return s.name }
+
+func (s *countingSink) Write(_ context.Context, rawJSON []byte, _ map[string]string) error {
+	n := atomic.AddInt32(&s.calls, 1)
+	s.lastRecord = rawJSON
+	if n <= s.failTimes {
+		return errors.New("simulated failure")
+	}
+	return nil
+}
+
+func TestSinkManagerWriteFanOut(t *testing.T) {
+	a := &countingSink{name: "a"}
+	b := &countingSink{name: "b"}
+	mgr := newSinkManager([]Sink{a, b}, false)
+	mgr.baseBackoff = time.Millisecond
+
+	if err := mgr.Write(context.Background(), []byte(`{"k":"v"}`), nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if atomic.LoadInt32(&a.calls) != 1 || atomic.LoadInt32(&b.calls) != 1 {
+		t.Errorf("expected both sinks to receive exactly one write, got a=%d b=%d", a.calls, b.calls)
+	}
+}
+
+func TestSinkManagerRetriesThenSucceeds(t *testing.T) {
+	s := &countingSink{name: "flaky", failTimes: 2}
+	mgr := newSinkManager([]Sink{s}, false)
+	mgr.baseBackoff = time.Millisecond
+
+	if err := mgr.Write(context.Background(), []byte(`{"k":"v"}`), nil); err != nil {
+		t.Fatalf("expected retry to eventually succeed, got %v", err)
+	}
+	if atomic.LoadInt32(&s.calls) != 3 {
+		t.Errorf("expected 3 attempts (2 failures + 1 success), got %d", s.calls)
+	}
+}
+
+func TestSinkManagerWriteFailsAfterExhaustingRetries(t *testing.T) {
+	s := &countingSink{name: "always-fails", failTimes: 100}
+	mgr := newSinkManager([]Sink{s}, false)
+	mgr.baseBackoff = time.Millisecond
+	mgr.maxRetries = 1
+
+	if err := mgr.Write(context.Background(), []byte(`{"k":"v"}`), nil); err == nil {
+		t.Error("expected an error once retries are exhausted")
+	}
+}
+
+func TestSinkManagerEnqueueReturnsImmediately(t *testing.T) {
+	s := &countingSink{name: "async"}
+	mgr := newSinkManager([]Sink{s}, true)
+
+	start := time.Now()
+	mgr.Enqueue([]byte(`{"k":"v"}`), nil)
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Enqueue should return immediately, took %v", elapsed)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&s.calls) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&s.calls) != 1 {
+		t.Errorf("expected the enqueued write to land asynchronously, got %d calls", s.calls)
+	}
+}
+
+// panicSink always panics on Write, to exercise panic containment in
+// dispatch's per-sink goroutines.
+type panicSink struct{}
+
+func (panicSink) Name() string { return "panic" }
+
+func (panicSink) Write(context.Context, []byte, map[string]string) error {
+	panic("simulated sink panic")
+}
+
+func TestSinkManagerWriteRecoversFromPanic(t *testing.T) {
+	mgr := newSinkManager([]Sink{panicSink{}}, false)
+	mgr.baseBackoff = time.Millisecond
+	mgr.maxRetries = 0
+
+	if err := mgr.Write(context.Background(), []byte(`{"k":"v"}`), nil); err == nil {
+		t.Error("expected a panic in a sink to surface as an error, not propagate")
+	}
+}
+
+func TestSinkManagerEnqueueRecoversFromPanic(t *testing.T) {
+	mgr := newSinkManager([]Sink{panicSink{}}, true)
+
+	// This must not crash the test process; it would if the async dispatch
+	// goroutine didn't recover the panic.
+	mgr.Enqueue([]byte(`{"k":"v"}`), nil)
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestSinkManagerDrainWaitsForEnqueuedWrites(t *testing.T) {
+	s := &countingSink{name: "async"}
+	mgr := newSinkManager([]Sink{s}, true)
+
+	mgr.Enqueue([]byte(`{"k":"v"}`), nil)
+
+	if err := mgr.Drain(context.Background()); err != nil {
+		t.Fatalf("expected Drain to return once the write finished, got %v", err)
+	}
+	if atomic.LoadInt32(&s.calls) != 1 {
+		t.Errorf("expected the enqueued write to have completed before Drain returned, got %d calls", s.calls)
+	}
+}
+
+func TestSinkManagerDrainTimesOut(t *testing.T) {
+	s := &countingSink{name: "async", failTimes: 100}
+	mgr := newSinkManager([]Sink{s}, true)
+	mgr.baseBackoff = time.Second
+	mgr.maxRetries = 100
+
+	// This write will retry for far longer than the context below allows.
+	mgr.Enqueue([]byte(`{"k":"v"}`), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := mgr.Drain(ctx); err == nil {
+		t.Error("expected Drain to time out while the write was still retrying")
+	}
+}
+
+func TestSinkManagerFromEnvDefaultsToStdout(t *testing.T) {
+	t.Setenv("LOG_SINK", "")
+	mgr, err := sinkManagerFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mgr.sinks) != 1 || mgr.sinks[0].Name() != "stdout" {
+		t.Errorf("expected a single default stdout sink, got %+v", mgr.sinks)
+	}
+}
+
+func TestSinkManagerFromEnvRejectsUnknownSink(t *testing.T) {
+	t.Setenv("LOG_SINK", "carrier-pigeon")
+	if _, err := sinkManagerFromEnv(); err == nil {
+		t.Error("expected an error for an unknown sink name")
+	}
+}